@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultCacheTTL is used when a module doesn't set cache_ttl explicitly.
+const defaultCacheTTL = 5 * time.Second
+
+type cacheEntry struct {
+	data    map[string]string
+	err     error
+	expires time.Time
+}
+
+// scrapeCache caches Driver.Status results per (driver, target) and
+// coalesces concurrent scrapes of the same target into a single backend
+// call, so cheap UPSs aren't hammered by multiple Prometheus replicas.
+type scrapeCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group
+}
+
+func newScrapeCache() *scrapeCache {
+	return &scrapeCache{entries: map[string]cacheEntry{}}
+}
+
+var globalScrapeCache = newScrapeCache()
+
+// Status returns a cached result for key (driverName, target, ups, username)
+// if it's still within ttl, otherwise it calls driver.Status, coalescing
+// with any other in-flight call for the same key. ttl <= 0 disables the
+// post-completion cache, but concurrent callers still share a single
+// in-flight call. ups and username are included so two modules probing the
+// same target but selecting a different UPS or credentials don't share a
+// cache entry or in-flight call.
+func (c *scrapeCache) Status(ctx context.Context, driverName, target, ups, username string, ttl time.Duration, driver Driver) (map[string]string, error) {
+	key := driverName + "|" + target + "|" + ups + "|" + username
+
+	if ttl > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && time.Now().Before(entry.expires) {
+			apcupsCacheHitsTotal.WithLabelValues(target).Inc()
+			return entry.data, entry.err
+		}
+	}
+	apcupsCacheMissesTotal.WithLabelValues(target).Inc()
+
+	v, err, shared := c.group.Do(key, func() (interface{}, error) {
+		data, err := driver.Status(ctx)
+		if ttl > 0 {
+			c.mu.Lock()
+			c.entries[key] = cacheEntry{data: data, err: err, expires: time.Now().Add(ttl)}
+			c.mu.Unlock()
+		}
+		return data, err
+	})
+	if shared {
+		apcupsScrapeCoalescedTotal.WithLabelValues(target).Inc()
+	}
+
+	if v == nil {
+		return nil, err
+	}
+	return v.(map[string]string), err
+}
+
+// cachedDriver wraps a Driver with scrapeCache, so collector.Collect need
+// not know caching exists.
+type cachedDriver struct {
+	name     string
+	target   string
+	ups      string
+	username string
+	ttl      time.Duration
+	driver   Driver
+	cache    *scrapeCache
+}
+
+func (d *cachedDriver) Status(ctx context.Context) (map[string]string, error) {
+	return d.cache.Status(ctx, d.name, d.target, d.ups, d.username, d.ttl, d.driver)
+}
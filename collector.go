@@ -1,13 +1,9 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"fmt"
 	"log"
-	"net"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +14,26 @@ import (
 type collector struct {
 	ctx    context.Context
 	target string
+	driver Driver
+
+	descs map[string]*prometheus.Desc
+	up    *prometheus.Desc
+}
+
+// newCollector builds the *prometheus.Desc for every known metric once, so
+// Describe and Collect always agree on what this collector can emit.
+func newCollector(ctx context.Context, target string, driver Driver) *collector {
+	descs := make(map[string]*prometheus.Desc, len(metrics))
+	for _, m := range metrics {
+		descs[m.id] = prometheus.NewDesc(m.name, m.descr, m.labels, nil)
+	}
+	return &collector{
+		ctx:    ctx,
+		target: target,
+		driver: driver,
+		descs:  descs,
+		up:     prometheus.NewDesc("apcups_up", "Whether the last scrape of the UPS succeeded", nil, nil),
+	}
 }
 
 // map[VERSION:3.14.10 (13 September 2011) debian MINTIMEL:3 Minutes BATTDATE:2014-10-21 END APC:2016-08-30 17 NUMXFERS:0 NOMPOWER:480 Watts NOMINV:230 Volts FIRMWARE:925.T1 .I USB FW APC:001,036,0923 STATUS:ONLINE BCHARGE:100.0 Percent TONBATT:0 seconds HOSTNAME:beaker.murf.org CABLE:USB Cable TIMELEFT:104.6 Minutes SELFTEST:NO ALARMDEL:30 seconds STATFLAG:0x07000008 Status Flag DATE:2016-08-30 17 UPSMODE:Stand Alone MAXTIME:0 Seconds SENSE:Medium HITRANS:280.0 Volts LASTXFER:Unacceptable line voltage changes XOFFBATT:N/A SERIALNO:3B1443X05291 UPSNAME:backups-950 DRIVER:USB UPS Driver STARTTIME:2016-08-30 16 LOADPCT:5.0 Percent Load Capacity MBATTCHG:5 Percent LOTRANS:155.0 Volts BATTV:13.5 Volts CUMONBATT:0 seconds MODEL:Back-UPS XS 950U LINEV:242.0 Volts NOMBATTV:12.0 Volts
@@ -47,6 +63,28 @@ type upsInfo struct {
 	lastTransfer string
 	batteryDate  string
 	numTransfers float64
+
+	selfTestResult string
+	lastSelfTest   time.Time
+	nextSelfTest   time.Time
+	lineFreq       float64
+	outputVoltage  float64
+	internalTemp   float64
+	hiTransfer     float64
+	loTransfer     float64
+	sense          string
+	alarmDel       time.Duration
+	minBattChgPct  float64
+	minTimeLeft    time.Duration
+	wakeDelay      time.Duration
+	shutdownDelay  time.Duration
+	statFlag       uint32
+
+	firmware   string
+	serialNo   string
+	cable      string
+	driverName string
+	apc        string
 }
 
 // See SVN code at https://sourceforge.net/p/apcupsd/svn/HEAD/tree/trunk/src/lib/apcstatus.c#l166 for
@@ -67,6 +105,35 @@ var statusList = []string{
 	"shutting down",
 }
 
+// selfTestResults are the values apcupsd's SELFTEST field can take.
+var selfTestResults = []string{"OK", "BT", "NG", "IP", "NO", "WN"}
+
+// senseLevels are the values apcupsd's SENSE (sensitivity) field can take.
+var senseLevels = []string{"Low", "Medium", "High"}
+
+// statFlagBit names a single bit of apcupsd's STATFLAG bitmask. Bit order
+// follows src/lib/apcstatus.c in the apcupsd source tree.
+type statFlagBit struct {
+	name string
+	mask uint32
+}
+
+var statFlagBits = []statFlagBit{
+	{"CAL", 1 << 0},
+	{"TRIM", 1 << 1},
+	{"BOOST", 1 << 2},
+	{"ONLINE", 1 << 3},
+	{"ONBATT", 1 << 4},
+	{"OVERLOAD", 1 << 5},
+	{"LOWBATT", 1 << 6},
+	{"REPLACEBATT", 1 << 7},
+	{"NOBATT", 1 << 8},
+	{"SLAVE", 1 << 9},
+	{"SLAVEDOWN", 1 << 10},
+	{"COMMLOST", 1 << 11},
+	{"SHUTTING_DOWN", 1 << 12},
+}
+
 var (
 	labels = []string{"hostname", "upsname"}
 )
@@ -178,41 +245,160 @@ var metrics = [...]metricType{
 		descr:     "Number of transfers to battery since apcupsd startup",
 		labels:    append(labels, "lasttransfer", "timetransfertobattery", "timetransferfrombattery"),
 	},
+	{
+		id:        "selfTest",
+		name:      "apcups_selftest_result",
+		valueType: prometheus.GaugeValue,
+		descr:     "Result of last self-test, one series per possible result",
+		labels:    append(labels, "result"),
+	},
+	{
+		id:        "lastSelfTest",
+		name:      "apcups_last_selftest_timestamp_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Unix timestamp of the last self-test",
+		labels:    labels,
+	},
+	{
+		id:        "nextSelfTest",
+		name:      "apcups_next_selftest_timestamp_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Unix timestamp of the next scheduled self-test",
+		labels:    labels,
+	},
+	{
+		id:        "lineFreq",
+		name:      "apcups_line_freq_hz",
+		valueType: prometheus.GaugeValue,
+		descr:     "UPS Line Frequency",
+		labels:    labels,
+	},
+	{
+		id:        "outputVoltage",
+		name:      "apcups_output_volts",
+		valueType: prometheus.GaugeValue,
+		descr:     "UPS Output Voltage",
+		labels:    labels,
+	},
+	{
+		id:        "internalTemp",
+		name:      "apcups_internal_temperature_celsius",
+		valueType: prometheus.GaugeValue,
+		descr:     "UPS Internal Temperature",
+		labels:    labels,
+	},
+	{
+		id:        "hiTransfer",
+		name:      "apcups_transfer_high_volts",
+		valueType: prometheus.GaugeValue,
+		descr:     "Line Voltage above which the UPS transfers to battery",
+		labels:    labels,
+	},
+	{
+		id:        "loTransfer",
+		name:      "apcups_transfer_low_volts",
+		valueType: prometheus.GaugeValue,
+		descr:     "Line Voltage below which the UPS transfers to battery",
+		labels:    labels,
+	},
+	{
+		id:        "sense",
+		name:      "apcups_sensitivity",
+		valueType: prometheus.GaugeValue,
+		descr:     "UPS sensitivity setting, one series per possible level",
+		labels:    append(labels, "level"),
+	},
+	{
+		id:        "alarmDel",
+		name:      "apcups_alarm_delay_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Delay before UPS alarm sounds after going on battery",
+		labels:    labels,
+	},
+	{
+		id:        "minBattChgPct",
+		name:      "apcups_min_battery_charge_percent",
+		valueType: prometheus.GaugeValue,
+		descr:     "Minimum battery charge percent required to power up after a shutdown",
+		labels:    labels,
+	},
+	{
+		id:        "minTimeLeft",
+		name:      "apcups_min_time_left_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Runtime remaining below which apcupsd initiates a shutdown",
+		labels:    labels,
+	},
+	{
+		id:        "wakeDelay",
+		name:      "apcups_wake_delay_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Delay before the UPS powers the load back up after a shutdown",
+		labels:    labels,
+	},
+	{
+		id:        "shutdownDelay",
+		name:      "apcups_shutdown_delay_seconds",
+		valueType: prometheus.GaugeValue,
+		descr:     "Delay before apcupsd shuts the system down after requesting one",
+		labels:    labels,
+	},
+	{
+		id:        "statFlagBit",
+		name:      "apcups_status_flag_bits",
+		valueType: prometheus.GaugeValue,
+		descr:     "Bits of the raw apcupsd STATFLAG status bitmask, one series per bit",
+		labels:    append(labels, "bit"),
+	},
+	{
+		id:        "info",
+		name:      "apcups_info",
+		valueType: prometheus.GaugeValue,
+		descr:     "Constant metric carrying UPS identification as labels",
+		labels:    append(labels, "firmware", "serialno", "cable", "driver", "apc"),
+	},
 }
 
-// Describe implements Prometheus.Collector.
-func (c collector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- prometheus.NewDesc("dummy", "dummy", nil, nil)
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	for _, d := range c.descs {
+		ch <- d
+	}
 }
 
-func (c collector) Collect(ch chan<- prometheus.Metric) {
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	gatherStart := time.Now()
 
-	data, _ := retrieveData(c.target)
-	gatherDuration := time.Now().Sub(gatherStart)
+	data, err := c.driver.Status(c.ctx)
+	var info *upsInfo
+	if err == nil {
+		info, err = transformData(data)
+	}
+	gatherDuration := time.Since(gatherStart)
 
-	info, _ := transformData(data)
-	log.Printf("%+v", info)
+	if err != nil {
+		log.Printf("Error scraping UPS at %s: %+v", c.target, err)
+		apcupsScrapeErrorsTotal.WithLabelValues(c.target).Inc()
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
 
 	for _, m := range metrics {
 		switch m.id {
 		case "status":
-			var v float64
-			var s string
 			for _, stat := range statusList {
+				v := 0.0
 				if stat == info.status {
 					v = 1
-					s = stat
-				} else {
-					v = 0
-					s = stat
 				}
+				ch <- prometheus.MustNewConstMetric(
+					c.descs[m.id],
+					m.valueType,
+					v,
+					info.hostname, info.upsName, stat, info.upsModel, info.batteryDate)
 			}
-			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
-				m.valueType,
-				v,
-				info.hostname, info.upsName, s, info.upsModel, info.batteryDate)
 		case "statusNumeric":
 			var v float64
 			var s string
@@ -223,82 +409,196 @@ func (c collector) Collect(ch chan<- prometheus.Metric) {
 				}
 			}
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				v,
 				info.hostname, info.upsName, s, info.upsModel, info.batteryDate)
 		case "collectSeconds":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				gatherDuration.Seconds(),
 				info.hostname, info.upsName)
 		case "nominalPower":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.nomPower,
 				info.hostname, info.upsName)
 		case "batteryChargePercent":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.batteryChargePercent,
 				info.hostname, info.upsName)
 		case "timeOnBattery":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.timeOnBattery.Seconds(),
 				info.hostname, info.upsName)
 		case "timeLeft":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.timeLeft.Seconds(),
 				info.hostname, info.upsName)
 		case "cumTimeOnBattery":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.cumTimeOnBattery.Seconds(),
 				info.hostname, info.upsName)
 		case "loadPercent":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.loadPercent,
 				info.hostname, info.upsName)
 		case "batteryVoltage":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.batteryVoltage,
 				info.hostname, info.upsName)
 		case "lineVoltage":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.lineVoltage,
 				info.hostname, info.upsName)
 		case "nomBatteryVoltage":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.nomBatteryVoltage,
 				info.hostname, info.upsName)
 		case "nomInputVoltage":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.nomInputVoltage,
 				info.hostname, info.upsName)
 		case "numTransfers":
 			ch <- prometheus.MustNewConstMetric(
-				prometheus.NewDesc(m.name, m.descr, m.labels, nil),
+				c.descs[m.id],
 				m.valueType,
 				info.numTransfers,
 				info.hostname, info.upsName, info.lastTransfer, info.timeTransferToBattery.Format("2006-01-02 15:04:05 -0700"), info.timeTransferFromBattery.Format("2006-01-02 15:04:05 -0700"))
+		case "selfTest":
+			for _, result := range selfTestResults {
+				v := 0.0
+				if result == info.selfTestResult {
+					v = 1
+				}
+				ch <- prometheus.MustNewConstMetric(
+					c.descs[m.id],
+					m.valueType,
+					v,
+					info.hostname, info.upsName, result)
+			}
+		case "lastSelfTest":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				float64(info.lastSelfTest.Unix()),
+				info.hostname, info.upsName)
+		case "nextSelfTest":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				float64(info.nextSelfTest.Unix()),
+				info.hostname, info.upsName)
+		case "lineFreq":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.lineFreq,
+				info.hostname, info.upsName)
+		case "outputVoltage":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.outputVoltage,
+				info.hostname, info.upsName)
+		case "internalTemp":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.internalTemp,
+				info.hostname, info.upsName)
+		case "hiTransfer":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.hiTransfer,
+				info.hostname, info.upsName)
+		case "loTransfer":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.loTransfer,
+				info.hostname, info.upsName)
+		case "sense":
+			for _, level := range senseLevels {
+				v := 0.0
+				if level == info.sense {
+					v = 1
+				}
+				ch <- prometheus.MustNewConstMetric(
+					c.descs[m.id],
+					m.valueType,
+					v,
+					info.hostname, info.upsName, level)
+			}
+		case "alarmDel":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.alarmDel.Seconds(),
+				info.hostname, info.upsName)
+		case "minBattChgPct":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.minBattChgPct,
+				info.hostname, info.upsName)
+		case "minTimeLeft":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.minTimeLeft.Seconds(),
+				info.hostname, info.upsName)
+		case "wakeDelay":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.wakeDelay.Seconds(),
+				info.hostname, info.upsName)
+		case "shutdownDelay":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				info.shutdownDelay.Seconds(),
+				info.hostname, info.upsName)
+		case "statFlagBit":
+			for _, bit := range statFlagBits {
+				v := 0.0
+				if info.statFlag&bit.mask != 0 {
+					v = 1
+				}
+				ch <- prometheus.MustNewConstMetric(
+					c.descs[m.id],
+					m.valueType,
+					v,
+					info.hostname, info.upsName, bit.name)
+			}
+		case "info":
+			ch <- prometheus.MustNewConstMetric(
+				c.descs[m.id],
+				m.valueType,
+				1,
+				info.hostname, info.upsName, info.firmware, info.serialNo, info.cable, info.driverName, info.apc)
 		}
 	}
 }
@@ -389,74 +689,130 @@ func transformData(ups map[string]string) (*upsInfo, error) {
 	}
 	upsInfo.batteryDate = ups["BATTDATE"]
 
-	return upsInfo, nil
-}
+	upsInfo.selfTestResult = ups["SELFTEST"]
 
-// parse time strings like 30 seconds or 1.25 minutes
-func parseTime(t string) (time.Duration, error) {
-	if t == "" {
-		return 0, nil
+	tLast, _ := time.Parse(timeForm, ups["LASTSTEST"])
+	upsInfo.lastSelfTest = tLast
+
+	tNext, _ := time.Parse(timeForm, ups["NEXTTEST"])
+	upsInfo.nextSelfTest = tNext
+
+	if freq, err := parseUnits(ups["LINEFREQ"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.lineFreq = freq
 	}
-	chunks := strings.Split(t, " ")
-	fmtStr := chunks[0] + string(strings.ToLower(chunks[1])[0])
-	return time.ParseDuration(fmtStr)
-}
 
-// parse generic units, splitting of units name and converting to float
-func parseUnits(v string) (float64, error) {
-	if v == "" {
-		return 0, nil
+	if volts, err := parseUnits(ups["OUTPUTV"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.outputVoltage = volts
 	}
-	return strconv.ParseFloat(strings.Split(v, " ")[0], 32)
-}
 
-func retrieveData(hostPort string) (map[string]string, error) {
-	conn, err := net.DialTimeout("tcp", hostPort, 10*time.Second)
-	if err != nil {
-		return nil, fmt.Errorf("Unable to connect to remote port: %+v", err)
+	if temp, err := parseUnits(ups["ITEMP"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.internalTemp = temp
 	}
 
-	if _, err = conn.Write([]byte{0, 6}); err != nil {
-		return nil, fmt.Errorf("Error writing command length: %+v", err)
+	if volts, err := parseUnits(ups["HITRANS"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.hiTransfer = volts
 	}
 
-	if _, err = conn.Write([]byte("status")); err != nil {
-		return nil, fmt.Errorf("Error writing command data: %+v", err)
+	if volts, err := parseUnits(ups["LOTRANS"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.loTransfer = volts
 	}
 
-	complete := false
-	upsData := map[string]string{}
+	upsInfo.sense = ups["SENSE"]
 
-	for !complete {
-		sizeBuf := []byte{0, 0}
-		var size int16
-		if _, err := conn.Read(sizeBuf); err != nil {
-			return nil, fmt.Errorf("Error reading size from incoming reader: %+v", err)
-		}
+	if d, err := parseTime(ups["ALARMDEL"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.alarmDel = d
+	}
 
-		if err = binary.Read(bytes.NewBuffer(sizeBuf), binary.BigEndian, &size); err != nil {
-			return nil, fmt.Errorf("Error decoding size in response: %+v", err)
-		}
+	if pct, err := parseUnits(ups["MBATTCHG"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.minBattChgPct = pct
+	}
 
-		if size > 0 {
-			data := make([]byte, size)
-			if _, err = conn.Read(data); err != nil {
-				log.Panicf("Error reading size from incoming reader: %+v", err)
-			}
+	if d, err := parseTime(ups["MINTIMEL"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.minTimeLeft = d
+	}
 
-			var re = regexp.MustCompile(`(?m)^([A-Z]*)\s*:\s*(.*)`)
-			matches := re.FindStringSubmatch(string(data))
-			if len(matches) >= 3 {
-				upsData[strings.TrimSpace(matches[1])] = strings.TrimSpace(matches[2])
-			}
-		} else {
-			complete = true
+	if d, err := parseTime(ups["DWAKE"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.wakeDelay = d
+	}
+
+	if d, err := parseTime(ups["DSHUTD"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.shutdownDelay = d
+	}
+
+	if flag, err := parseStatFlag(ups["STATFLAG"]); err != nil {
+		return nil, err
+	} else {
+		upsInfo.statFlag = flag
+	}
+
+	upsInfo.firmware = ups["FIRMWARE"]
+	upsInfo.serialNo = ups["SERIALNO"]
+	upsInfo.cable = ups["CABLE"]
+	upsInfo.driverName = ups["DRIVER"]
+	upsInfo.apc = ups["APC"]
+
+	return upsInfo, nil
+}
+
+// parseTime parses apcupsd duration fields like "30 seconds", "1.25 minutes"
+// or "3 Days". Go's time.ParseDuration has no "days" unit, so that case is
+// handled separately.
+func parseTime(t string) (time.Duration, error) {
+	if t == "" {
+		return 0, nil
+	}
+	chunks := strings.Split(t, " ")
+	if len(chunks) < 2 {
+		return 0, fmt.Errorf("invalid time value %q", t)
+	}
+	if strings.EqualFold(chunks[1], "days") {
+		days, err := strconv.ParseFloat(chunks[0], 64)
+		if err != nil {
+			return 0, err
 		}
+		return time.Duration(days * 24 * float64(time.Hour)), nil
 	}
+	fmtStr := chunks[0] + string(strings.ToLower(chunks[1])[0])
+	return time.ParseDuration(fmtStr)
+}
 
-	if err = conn.Close(); err != nil {
-		log.Panicf("Error closing apcupsd connection: %+v", err)
+// parseStatFlag parses apcupsd's STATFLAG field, e.g. "0x07000008 Status Flag".
+func parseStatFlag(v string) (uint32, error) {
+	if v == "" {
+		return 0, nil
 	}
+	flag, err := strconv.ParseUint(strings.Split(v, " ")[0], 0, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(flag), nil
+}
 
-	return upsData, nil
+// parse generic units, splitting of units name and converting to float
+func parseUnits(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.Split(v, " ")[0], 32)
 }
+
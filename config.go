@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config is the top-level structure of the --config.file, in the same
+// spirit as the blackbox_exporter's module file: a map of named modules,
+// each describing how to scrape one kind of target.
+type Config struct {
+	Modules map[string]Module `yaml:"modules"`
+}
+
+// Module describes how to probe a target: which driver to speak, and any
+// credentials/timeouts/TLS settings that driver needs.
+type Module struct {
+	Driver   string    `yaml:"driver"`
+	Timeout  Duration  `yaml:"timeout,omitempty"`
+	Username string    `yaml:"username,omitempty"`
+	Password string    `yaml:"password,omitempty"`
+	TLS      TLSConfig `yaml:"tls,omitempty"`
+	// UPS is the device name to query, for drivers (like NUT) that can
+	// manage more than one UPS per daemon.
+	UPS string `yaml:"ups,omitempty"`
+	// CacheTTL overrides defaultCacheTTL for this module's scrapes. nil
+	// means "use the default"; an explicit 0 disables caching.
+	CacheTTL *Duration `yaml:"cache_ttl,omitempty"`
+}
+
+// Duration wraps time.Duration so module config can use human-readable
+// strings like "5s" or "1m30s" instead of raw nanosecond counts.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %+v", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// TLSConfig carries the optional TLS settings a driver may use, e.g. NUT's
+// STARTTLS handshake.
+type TLSConfig struct {
+	Enable             bool   `yaml:"enable,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+}
+
+// safeConfig guards the active Config behind a RWMutex so it can be hot
+// reloaded (SIGHUP or /-/reload) while probes are in flight.
+type safeConfig struct {
+	mu sync.RWMutex
+	c  *Config
+}
+
+func (sc *safeConfig) config() *Config {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.c
+}
+
+func (sc *safeConfig) reloadConfig(configFile string) error {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	sc.mu.Lock()
+	sc.c = config
+	sc.mu.Unlock()
+	return nil
+}
+
+func loadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %+v", err)
+	}
+
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("parsing config file: %+v", err)
+	}
+
+	for name, module := range config.Modules {
+		if module.Driver == "" {
+			module.Driver = "apcupsd"
+			config.Modules[name] = module
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// Driver abstracts a UPS status backend. Implementations connect to a UPS
+// monitoring daemon and return its raw status fields, keyed the same way the
+// apcupsd NIS protocol keys them (e.g. STATUS, BCHARGE, LINEV), so a single
+// transformData can turn the result of any driver into an upsInfo.
+type Driver interface {
+	Status(ctx context.Context) (map[string]string, error)
+}
+
+// driverOptions carries the credentials/TLS settings a Driver may need,
+// sourced from a Module's config (or left zero for the legacy /apcupsd
+// endpoint, which has no config to take them from).
+type driverOptions struct {
+	Username string
+	Password string
+	TLS      TLSConfig
+}
+
+// newDriver builds the Driver selected by name, defaulting to apcupsd for
+// backward compatibility with scrape URLs that don't specify one.
+func newDriver(name, target, ups string, opts driverOptions) (Driver, error) {
+	switch name {
+	case "", "apcupsd":
+		return &apcupsdDriver{target: target}, nil
+	case "nut":
+		return &nutDriver{
+			target:   target,
+			ups:      ups,
+			username: opts.Username,
+			password: opts.Password,
+			tls:      opts.TLS,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown driver %q, expected apcupsd or nut", name)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// apcupsdDriver speaks the apcupsd NIS protocol (the exporter's original and
+// default backend).
+type apcupsdDriver struct {
+	target string
+}
+
+func (d *apcupsdDriver) Status(ctx context.Context) (map[string]string, error) {
+	conn, err := net.DialTimeout("tcp", d.target, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to remote port: %+v", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if done := ctx.Done(); done != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-done:
+				conn.SetDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+	}
+
+	if _, err := conn.Write([]byte{0, 6}); err != nil {
+		return nil, fmt.Errorf("error writing command length: %+v", err)
+	}
+	if _, err := conn.Write([]byte("status")); err != nil {
+		return nil, fmt.Errorf("error writing command data: %+v", err)
+	}
+
+	data, err := readNISFrames(conn)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return data, nil
+}
+
+// readNISFrames reads length-prefixed NIS frames from r until it sees the
+// zero-length terminator frame, parsing each frame as one "KEY : VALUE"
+// status line. It uses io.ReadFull so short reads can't corrupt the length
+// prefix or a frame's payload.
+func readNISFrames(r io.Reader) (map[string]string, error) {
+	upsData := map[string]string{}
+
+	for {
+		sizeBuf := make([]byte, 2)
+		if _, err := io.ReadFull(r, sizeBuf); err != nil {
+			return nil, fmt.Errorf("error reading frame size: %+v", err)
+		}
+		size := binary.BigEndian.Uint16(sizeBuf)
+		if size == 0 {
+			return upsData, nil
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, fmt.Errorf("error reading frame payload: %+v", err)
+		}
+
+		if key, value, ok := parseNISLine(string(payload)); ok {
+			upsData[key] = value
+		}
+	}
+}
+
+// parseNISLine parses a single "KEY : VALUE" status line, where KEY matches
+// [A-Z0-9]+. It reports ok=false for lines it can't make sense of, which
+// readNISFrames treats as "skip this frame" rather than an error.
+func parseNISLine(line string) (key, value string, ok bool) {
+	idx := strings.IndexByte(line, ':')
+	if idx < 0 {
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(line[:idx])
+	if key == "" || !isUpperAlnum(key) {
+		return "", "", false
+	}
+
+	value = strings.TrimSpace(line[idx+1:])
+	return key, value, true
+}
+
+func isUpperAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
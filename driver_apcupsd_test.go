@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// nisFrame builds a single length-prefixed NIS frame for payload.
+func nisFrame(payload string) []byte {
+	buf := make([]byte, 2+len(payload))
+	buf[0] = byte(len(payload) >> 8)
+	buf[1] = byte(len(payload))
+	copy(buf[2:], payload)
+	return buf
+}
+
+// shortReader returns at most n bytes per Read call, to exercise the
+// io.ReadFull short-read handling in readNISFrames.
+type shortReader struct {
+	r io.Reader
+	n int
+}
+
+func (s *shortReader) Read(p []byte) (int, error) {
+	if len(p) > s.n {
+		p = p[:s.n]
+	}
+	return s.r.Read(p)
+}
+
+func TestReadNISFramesFullStream(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(nisFrame("STATUS   : ONLINE"))
+	buf.Write(nisFrame("BATTV    : 13.5 Volts"))
+	buf.Write(nisFrame("NUMXFERS : 0"))
+	buf.Write([]byte{0, 0}) // terminator frame
+
+	got, err := readNISFrames(&buf)
+	if err != nil {
+		t.Fatalf("readNISFrames: %+v", err)
+	}
+
+	want := map[string]string{
+		"STATUS":   "ONLINE",
+		"BATTV":    "13.5 Volts",
+		"NUMXFERS": "0",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("key %s: got %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestReadNISFramesShortReads(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(nisFrame("HOSTNAME : beaker.murf.org"))
+	buf.Write(nisFrame("NOMPOWER : 480 Watts"))
+	buf.Write([]byte{0, 0})
+
+	r := &shortReader{r: &buf, n: 3}
+
+	got, err := readNISFrames(r)
+	if err != nil {
+		t.Fatalf("readNISFrames with short reads: %+v", err)
+	}
+
+	if got["HOSTNAME"] != "beaker.murf.org" {
+		t.Errorf("HOSTNAME: got %q", got["HOSTNAME"])
+	}
+	if got["NOMPOWER"] != "480 Watts" {
+		t.Errorf("NOMPOWER: got %q", got["NOMPOWER"])
+	}
+}
+
+func TestReadNISFramesTruncatedStream(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(nisFrame("STATUS   : ONLINE"))
+	// No terminator frame: stream ends mid-protocol.
+
+	if _, err := readNISFrames(&buf); err == nil {
+		t.Fatal("expected an error for a truncated stream, got nil")
+	}
+}
+
+func TestParseNISLine(t *testing.T) {
+	cases := []struct {
+		line      string
+		wantKey   string
+		wantValue string
+		wantOK    bool
+	}{
+		{"STATUS   : ONLINE", "STATUS", "ONLINE", true},
+		{"NUMXFERS : 0", "NUMXFERS", "0", true},
+		{"APC      : 001,036,0923", "APC", "001,036,0923", true},
+		{"XFER2    : test", "XFER2", "test", true},
+		{"no colon here", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, c := range cases {
+		key, value, ok := parseNISLine(c.line)
+		if ok != c.wantOK || key != c.wantKey || value != c.wantValue {
+			t.Errorf("parseNISLine(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				c.line, key, value, ok, c.wantKey, c.wantValue, c.wantOK)
+		}
+	}
+}
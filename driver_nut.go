@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+)
+
+// nutDriver speaks the Network UPS Tools (NUT) upsd protocol, for UPS units
+// managed through NUT rather than apcupsd.
+type nutDriver struct {
+	target   string
+	ups      string
+	username string
+	password string
+	tls      TLSConfig
+}
+
+// nutStatusTranslation maps NUT "ups.status" tokens to this exporter's
+// canonical status strings (see statusList in collector.go). "cal" has no
+// entry in statusList, so while calibrating every apcups_status series is 0.
+var nutStatusTranslation = map[string]string{
+	"OL":    "online",
+	"OB":    "onbatt",
+	"LB":    "lowbatt",
+	"RB":    "replacebatt",
+	"CAL":   "cal",
+	"TRIM":  "trim",
+	"BOOST": "boost",
+	"OVER":  "overload",
+}
+
+func (d *nutDriver) Status(ctx context.Context) (map[string]string, error) {
+	conn, err := net.DialTimeout("tcp", d.target, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to NUT upsd at %s: %+v", d.target, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if done := ctx.Done(); done != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-done:
+				conn.SetDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if d.tls.Enable {
+		if _, err := nutCommand(rw, "STARTTLS"); err != nil {
+			return nil, fmt.Errorf("NUT STARTTLS failed: %+v", err)
+		}
+		tlsConf, err := newNUTTLSConfig(d.tls, d.target)
+		if err != nil {
+			return nil, fmt.Errorf("NUT STARTTLS config: %+v", err)
+		}
+		tlsConn := tls.Client(conn, tlsConf)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("NUT STARTTLS handshake failed: %+v", err)
+		}
+		rw = bufio.NewReadWriter(bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn))
+	}
+
+	if d.username != "" {
+		if _, err := nutCommand(rw, fmt.Sprintf("USERNAME %s", d.username)); err != nil {
+			return nil, fmt.Errorf("NUT USERNAME failed: %+v", err)
+		}
+		if _, err := nutCommand(rw, fmt.Sprintf("PASSWORD %s", d.password)); err != nil {
+			return nil, fmt.Errorf("NUT PASSWORD failed: %+v", err)
+		}
+	}
+
+	ups := d.ups
+	if ups == "" {
+		upsList, err := nutListUPS(rw)
+		if err != nil {
+			return nil, err
+		}
+		if len(upsList) == 0 {
+			return nil, fmt.Errorf("no UPS devices reported by %s", d.target)
+		}
+		ups = upsList[0]
+	}
+
+	vars, err := nutListVar(rw, ups)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return translateNUTVars(d.target, ups, vars), nil
+}
+
+// newNUTTLSConfig builds a *tls.Config from a module's TLSConfig, loading
+// any configured CA and client certificate from disk. target is the
+// driver's "host:port" address, used to derive the ServerName for
+// certificate verification.
+func newNUTTLSConfig(cfg TLSConfig, target string) (*tls.Config, error) {
+	serverName := target
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		serverName = host
+	}
+
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		ServerName:         serverName,
+	}
+
+	if cfg.CAFile != "" {
+		caCert, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_file: %+v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in ca_file %s", cfg.CAFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading cert_file/key_file: %+v", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConf, nil
+}
+
+// nutCommand sends a single-line NUT command and returns its (non-list)
+// reply line.
+func nutCommand(rw *bufio.ReadWriter, cmd string) (string, error) {
+	if _, err := rw.WriteString(cmd + "\n"); err != nil {
+		return "", err
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "ERR ") {
+		return "", fmt.Errorf("NUT server error: %s", strings.TrimPrefix(line, "ERR "))
+	}
+	return line, nil
+}
+
+// nutListUPS issues "LIST UPS" and returns the names of the UPS devices
+// upsd knows about.
+func nutListUPS(rw *bufio.ReadWriter) ([]string, error) {
+	var upsNames []string
+	err := nutList(rw, "LIST UPS", "UPS", func(fields []string) error {
+		if len(fields) < 1 {
+			return nil
+		}
+		upsNames = append(upsNames, fields[0])
+		return nil
+	})
+	return upsNames, err
+}
+
+// nutListVar issues "LIST VAR <ups>" and returns the variable/value pairs
+// reported for that UPS.
+func nutListVar(rw *bufio.ReadWriter, ups string) (map[string]string, error) {
+	vars := map[string]string{}
+	err := nutList(rw, fmt.Sprintf("LIST VAR %s", ups), "VAR", func(fields []string) error {
+		if len(fields) < 3 {
+			return nil
+		}
+		// fields: <ups> <varname> "<value>"
+		vars[fields[1]] = strings.Trim(strings.Join(fields[2:], " "), `"`)
+		return nil
+	})
+	return vars, err
+}
+
+// nutList drives a NUT "LIST <subtype> ..." request/response: it sends cmd,
+// expects a "BEGIN LIST <subtype>" echo, feeds each "<subtype> <fields...>"
+// line to handle, and stops at "END LIST <subtype>".
+func nutList(rw *bufio.ReadWriter, cmd, subtype string, handle func(fields []string) error) error {
+	if _, err := rw.WriteString(cmd + "\n"); err != nil {
+		return err
+	}
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+
+	begin, err := rw.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading NUT %s reply: %+v", subtype, err)
+	}
+	begin = strings.TrimRight(begin, "\r\n")
+	if strings.HasPrefix(begin, "ERR ") {
+		return fmt.Errorf("NUT server error: %s", strings.TrimPrefix(begin, "ERR "))
+	}
+	if !strings.HasPrefix(begin, "BEGIN LIST "+subtype) {
+		return fmt.Errorf("unexpected NUT reply to %q: %q", cmd, begin)
+	}
+
+	endMarker := "END LIST " + subtype
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading NUT %s list: %+v", subtype, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == endMarker {
+			return nil
+		}
+		rest := strings.TrimPrefix(line, subtype+" ")
+		if rest == line {
+			continue
+		}
+		if err := handle(nutSplitFields(rest)); err != nil {
+			return err
+		}
+	}
+}
+
+// nutSplitFields splits a NUT list line into fields, keeping quoted values
+// (which may contain spaces) intact.
+func nutSplitFields(s string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ' ' && !inQuotes:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// translateNUTVars maps NUT variable names into the apcupsd STATUS-style
+// keys transformData expects, so both drivers share one parsing path. target
+// becomes the HOSTNAME label, since NUT has no equivalent of apcupsd's
+// monitoring-host HOSTNAME field (device.model/device.mfr describe the UPS
+// itself, not a host).
+func translateNUTVars(target, ups string, vars map[string]string) map[string]string {
+	data := map[string]string{"HOSTNAME": target}
+
+	if status, ok := vars["ups.status"]; ok {
+		var canonical []string
+		for _, token := range strings.Fields(status) {
+			if s, ok := nutStatusTranslation[token]; ok {
+				canonical = append(canonical, s)
+			}
+		}
+		if len(canonical) > 0 {
+			data["STATUS"] = canonical[0]
+		}
+	}
+
+	copyVar := func(key, nutVar, unit string) {
+		if v, ok := vars[nutVar]; ok {
+			if unit != "" {
+				v = v + " " + unit
+			}
+			data[key] = v
+		}
+	}
+
+	copyVar("BCHARGE", "battery.charge", "Percent")
+	copyVar("BATTV", "battery.voltage", "Volts")
+	copyVar("NOMBATTV", "battery.voltage.nominal", "Volts")
+	copyVar("LINEV", "input.voltage", "Volts")
+	copyVar("NOMINV", "input.voltage.nominal", "Volts")
+	copyVar("LOADPCT", "ups.load", "Percent")
+	copyVar("TIMELEFT", "battery.runtime", "seconds")
+	copyVar("NOMPOWER", "ups.realpower.nominal", "Watts")
+	copyVar("MODEL", "ups.model", "")
+	copyVar("SERIALNO", "ups.serial", "")
+	copyVar("DRIVER", "driver.name", "")
+	copyVar("FIRMWARE", "ups.firmware", "")
+
+	data["UPSNAME"] = ups
+
+	return data
+}
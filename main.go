@@ -1,16 +1,60 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"hash/crc32"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	yaml "gopkg.in/yaml.v2"
 )
 
+var (
+	configReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "config_last_reload_successful",
+		Help: "Whether the last configuration file reload attempt succeeded.",
+	})
+	moduleConfigHash = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "apcups_module_config_hash",
+		Help: "CRC32 hash of the currently loaded configuration for a module, to detect config drift across replicas.",
+	}, []string{"module"})
+	apcupsScrapeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apcups_scrape_error_total",
+		Help: "Number of errors while scraping a UPS target.",
+	}, []string{"target"})
+	apcupsCacheHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apcups_cache_hits_total",
+		Help: "Number of scrapes served from the per-target cache.",
+	}, []string{"target"})
+	apcupsCacheMissesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apcups_cache_misses_total",
+		Help: "Number of scrapes that had to hit the UPS backend.",
+	}, []string{"target"})
+	apcupsScrapeCoalescedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apcups_scrape_coalesced_total",
+		Help: "Number of concurrent scrapes that shared an in-flight backend call instead of issuing their own.",
+	}, []string{"target"})
+)
+
+func init() {
+	prometheus.MustRegister(configReloadSuccess)
+	prometheus.MustRegister(moduleConfigHash)
+	prometheus.MustRegister(apcupsScrapeErrorsTotal)
+	prometheus.MustRegister(apcupsCacheHitsTotal)
+	prometheus.MustRegister(apcupsCacheMissesTotal)
+	prometheus.MustRegister(apcupsScrapeCoalescedTotal)
+}
+
+// handler implements the legacy /apcupsd?target=&port= scrape shape, kept
+// as a deprecated alias of /probe for one release.
 func handler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -27,14 +71,23 @@ func handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	upsAddr := target + ":" + port
-	// upsAddr := flag.String("ups-address", "localhost:3551", "The address of the acupsd daemon to query: hostname:port")
-	flag.Parse()
 
-	log.Printf("Connection to UPS at: %s", upsAddr)
+	driverName := query.Get("driver")
+	ups := query.Get("ups")
+
+	log.Printf("DEPRECATED: /apcupsd?target=&port= is deprecated, use /probe?target=&module= instead")
+	log.Printf("Connection to UPS at: %s (driver=%s)", upsAddr, driverName)
+
+	driver, err := newDriver(driverName, upsAddr, ups, driverOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	driver = &cachedDriver{name: driverName, target: upsAddr, ups: ups, ttl: defaultCacheTTL, driver: driver, cache: globalScrapeCache}
 
 	start := time.Now()
 	registry := prometheus.NewRegistry()
-	collector := collector{ctx: r.Context(), target: upsAddr}
+	collector := newCollector(r.Context(), upsAddr, driver)
 	registry.MustRegister(collector)
 	// Delegate http serving to Prometheus client library, which will call collector.Collect.
 	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
@@ -43,14 +96,133 @@ func handler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Finished scrape in %+v seconds", duration)
 }
 
+// probeHandler implements /probe?target=&module=, resolving the module's
+// driver and credentials from the loaded Config.
+func probeHandler(w http.ResponseWriter, r *http.Request, sc *safeConfig) {
+	query := r.URL.Query()
+
+	target := query.Get("target")
+	if target == "" {
+		http.Error(w, "'target' parameter must be specified", 400)
+		return
+	}
+
+	moduleName := query.Get("module")
+	if moduleName == "" {
+		http.Error(w, "'module' parameter must be specified", 400)
+		return
+	}
+
+	module, ok := sc.config().Modules[moduleName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), 400)
+		return
+	}
+
+	ctx := r.Context()
+	if module.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(module.Timeout))
+		defer cancel()
+	}
+
+	ups := module.UPS
+	if q := query.Get("ups"); q != "" {
+		ups = q
+	}
+
+	driver, err := newDriver(module.Driver, target, ups, driverOptions{
+		Username: module.Username,
+		Password: module.Password,
+		TLS:      module.TLS,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	ttl := defaultCacheTTL
+	if module.CacheTTL != nil {
+		ttl = time.Duration(*module.CacheTTL)
+	}
+	driver = &cachedDriver{name: module.Driver, target: target, ups: ups, username: module.Username, ttl: ttl, driver: driver, cache: globalScrapeCache}
+
+	log.Printf("Probing UPS at: %s (module=%s, driver=%s)", target, moduleName, module.Driver)
+
+	start := time.Now()
+	registry := prometheus.NewRegistry()
+	collector := newCollector(ctx, target, driver)
+	registry.MustRegister(collector)
+	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	h.ServeHTTP(w, r)
+	duration := time.Since(start).Seconds()
+	log.Printf("Finished probe in %+v seconds", duration)
+}
+
+// reloadHandler implements /-/reload, so operators can trigger a config
+// reload without sending SIGHUP.
+func reloadHandler(configFile string, sc *safeConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			http.Error(w, "This endpoint requires a POST or PUT request.", 405)
+			return
+		}
+		if err := reloadConfigAndMetrics(configFile, sc); err != nil {
+			http.Error(w, fmt.Sprintf("failed to reload config: %+v", err), 500)
+			return
+		}
+	}
+}
+
+// reloadConfigAndMetrics reloads configFile into sc and updates the
+// config_last_reload_successful and apcups_module_config_hash metrics to
+// reflect the outcome.
+func reloadConfigAndMetrics(configFile string, sc *safeConfig) error {
+	if err := sc.reloadConfig(configFile); err != nil {
+		log.Printf("Error reloading config file %s: %+v", configFile, err)
+		configReloadSuccess.Set(0)
+		return err
+	}
+
+	moduleConfigHash.Reset()
+	for name, module := range sc.config().Modules {
+		data, _ := yaml.Marshal(module)
+		moduleConfigHash.WithLabelValues(name).Set(float64(crc32.ChecksumIEEE(data)))
+	}
+	configReloadSuccess.Set(1)
+	log.Printf("Reloaded config file %s", configFile)
+	return nil
+}
+
 func main() {
 	// TODO: Register a port for listening here: https://github.com/prometheus/prometheus/wiki/Default-port-allocations
 	addr := flag.String("listen-address", ":8080", "The address to listen on for HTTP requests.")
+	configFile := flag.String("config.file", "", "Path to the module configuration file. If unset, only the deprecated /apcupsd?target=&port= endpoint is available.")
 	flag.Parse()
 	log.Printf("Metric listener at: %s", *addr)
 
+	sc := &safeConfig{c: &Config{Modules: map[string]Module{}}}
+	if *configFile != "" {
+		if err := reloadConfigAndMetrics(*configFile, sc); err != nil {
+			log.Fatalf("Error loading config file %s: %+v", *configFile, err)
+		}
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				reloadConfigAndMetrics(*configFile, sc)
+			}
+		}()
+	}
+
 	http.Handle("/metrics", promhttp.Handler()) // Normal metrics endpoint for APC-UPSD exporter itself.
-	// Endpoint to do SNMP scrapes.
+	// Endpoint to do UPS scrapes, driven by the loaded module config.
+	http.HandleFunc("/probe", func(w http.ResponseWriter, r *http.Request) {
+		probeHandler(w, r, sc)
+	})
+	http.HandleFunc("/-/reload", reloadHandler(*configFile, sc))
+	// Deprecated: use /probe?target=&module= instead.
 	http.HandleFunc("/apcupsd", func(w http.ResponseWriter, r *http.Request) {
 		handler(w, r)
 	})